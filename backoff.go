@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	initialBackoff = 15 * time.Second
+	maxBackoff     = 15 * time.Minute
+)
+
+// backoffForFailures returns the retry delay for the given number of
+// consecutive failures: 15s, 30s, 1m, 2m, ..., doubling each time and
+// capped at maxBackoff.
+func backoffForFailures(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	d := initialBackoff
+	for i := 1; i < failures; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
+
+// handleFetchFailure logs err, bumps the consecutive-failure count,
+// redisplays the last cached image (with an "offline since" overlay on
+// the native framebuffer path) if one is available, and sleeps for the
+// resulting backoff interval before the caller retries.
+func handleFetchFailure(ctx context.Context, options AppOptions, state *DaemonState, cache *Cache, label string, err error) {
+	fmt.Printf("%s: %v\n", label, err)
+
+	failures := state.IncrementFailures()
+	backoff := backoffForFailures(failures)
+
+	if cache != nil {
+		if _, imagePath, _, ok := cache.Load(); ok {
+			if showErr := redisplayCachedImage(imagePath, options, state); showErr != nil && options.Verbose {
+				fmt.Printf("Error redisplaying cached image: %v\n", showErr)
+			}
+		}
+	}
+
+	if options.Verbose {
+		fmt.Printf("Retrying in %s (%d consecutive failures)\n", backoff, failures)
+	}
+	sleepOrDone(ctx, backoff)
+}
+
+// redisplayCachedImage shows the cached image again and, on the native
+// framebuffer path, stamps an "offline since" overlay over it.
+func redisplayCachedImage(imagePath string, options AppOptions, state *DaemonState) error {
+	state.SetUsingCache(true)
+
+	if err := displayImage(imagePath, options); err != nil {
+		return err
+	}
+
+	if options.Renderer == "fb" {
+		if fb, err := OpenFramebuffer(""); err == nil {
+			drawOfflineOverlay(fb, state.LastSuccessAt())
+			fb.Close()
+		}
+	}
+	return nil
+}
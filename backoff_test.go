@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffForFailures(t *testing.T) {
+	cases := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, 15 * time.Second},
+		{2, 30 * time.Second},
+		{3, time.Minute},
+		{4, 2 * time.Minute},
+		{5, 4 * time.Minute},
+		{6, 8 * time.Minute},
+		{7, maxBackoff},
+		{20, maxBackoff},
+	}
+	for _, c := range cases {
+		if got := backoffForFailures(c.failures); got != c.want {
+			t.Errorf("backoffForFailures(%d) = %s, want %s", c.failures, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache persists the most recent successful TerminalResponse and a copy
+// of its downloaded image file under a single directory, so the daemon can
+// redisplay something meaningful across restarts and fetch failures
+// instead of going blank.
+type Cache struct {
+	dir string
+}
+
+// cacheMeta is the on-disk JSON sidecar stored alongside the cached
+// image.
+type cacheMeta struct {
+	TerminalResponse
+	ImagePath string    `json:"image_path"`
+	SavedAt   time.Time `json:"saved_at"`
+}
+
+// NewCache creates dir if necessary and returns a Cache backed by it.
+func NewCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// defaultCacheDir returns $XDG_CACHE_HOME/trmnl, falling back to
+// $HOME/.cache/trmnl per the XDG base directory spec.
+func defaultCacheDir() (string, error) {
+	cacheHome := os.Getenv("XDG_CACHE_HOME")
+	if cacheHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to determine home directory: %v", err)
+		}
+		cacheHome = filepath.Join(homeDir, ".cache")
+	}
+	return filepath.Join(cacheHome, "trmnl"), nil
+}
+
+func (c *Cache) metaPath() string {
+	return filepath.Join(c.dir, "last.json")
+}
+
+// imagePath returns the stable on-disk path for the cached image, using
+// ext (derived from the upstream filename) so decoders that key off the
+// file extension keep working. The base name never changes, so Store
+// never accumulates one file per distinct upstream filename.
+func (c *Cache) imagePath(ext string) string {
+	return filepath.Join(c.dir, "last_image"+ext)
+}
+
+// Store copies the image at srcImagePath into the cache and records
+// terminal's metadata alongside it, replacing whatever was previously
+// cached. terminal.Filename is kept only in the metadata for display
+// purposes; the cached image itself always lives at the same path so a
+// long-running daemon doesn't accumulate one file per distinct upstream
+// filename.
+func (c *Cache) Store(terminal TerminalResponse, srcImagePath string) error {
+	filename := terminal.Filename
+	if filename == "" {
+		filename = "display.jpg"
+	}
+	ext := filepath.Ext(filename)
+	if ext == "" {
+		ext = filepath.Ext(srcImagePath)
+	}
+
+	if stale, err := filepath.Glob(filepath.Join(c.dir, "last_image.*")); err == nil {
+		for _, f := range stale {
+			os.Remove(f)
+		}
+	}
+	dstImagePath := c.imagePath(ext)
+
+	data, err := os.ReadFile(srcImagePath)
+	if err != nil {
+		return fmt.Errorf("failed to read image for caching: %v", err)
+	}
+	if err := os.WriteFile(dstImagePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cached image: %v", err)
+	}
+
+	meta := cacheMeta{
+		TerminalResponse: terminal,
+		ImagePath:        dstImagePath,
+		SavedAt:          time.Now(),
+	}
+	meta.Filename = filename
+
+	metaData, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache metadata: %v", err)
+	}
+	if err := os.WriteFile(c.metaPath(), metaData, 0644); err != nil {
+		return fmt.Errorf("failed to write cache metadata: %v", err)
+	}
+	return nil
+}
+
+// Load returns the most recently cached TerminalResponse, the local
+// path of its image, and when it was cached. ok is false if nothing has
+// been cached yet (or the cached image has since disappeared).
+func (c *Cache) Load() (terminal TerminalResponse, imagePath string, savedAt time.Time, ok bool) {
+	data, err := os.ReadFile(c.metaPath())
+	if err != nil {
+		return TerminalResponse{}, "", time.Time{}, false
+	}
+
+	var meta cacheMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return TerminalResponse{}, "", time.Time{}, false
+	}
+	if _, err := os.Stat(meta.ImagePath); err != nil {
+		return TerminalResponse{}, "", time.Time{}, false
+	}
+
+	return meta.TerminalResponse, meta.ImagePath, meta.SavedAt, true
+}
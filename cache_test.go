@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCacheStoreLoadRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewCache(dir)
+	if err != nil {
+		t.Fatalf("NewCache: unexpected error: %v", err)
+	}
+
+	srcImage := filepath.Join(dir, "src.jpg")
+	want := []byte("fake jpeg bytes")
+	if err := os.WriteFile(srcImage, want, 0644); err != nil {
+		t.Fatalf("failed to write source image: %v", err)
+	}
+
+	terminal := TerminalResponse{
+		ImageURL:    "https://example.com/image.jpg",
+		Filename:    "display.jpg",
+		RefreshRate: 300,
+	}
+
+	if err := cache.Store(terminal, srcImage); err != nil {
+		t.Fatalf("Store: unexpected error: %v", err)
+	}
+
+	gotTerminal, gotImagePath, savedAt, ok := cache.Load()
+	if !ok {
+		t.Fatal("Load: ok = false after Store")
+	}
+	if gotTerminal != terminal {
+		t.Errorf("Load terminal = %+v, want %+v", gotTerminal, terminal)
+	}
+	if savedAt.IsZero() {
+		t.Error("Load savedAt is zero")
+	}
+
+	gotData, err := os.ReadFile(gotImagePath)
+	if err != nil {
+		t.Fatalf("failed to read cached image at %q: %v", gotImagePath, err)
+	}
+	if string(gotData) != string(want) {
+		t.Errorf("cached image bytes = %q, want %q", gotData, want)
+	}
+}
+
+func TestCacheLoadEmpty(t *testing.T) {
+	cache, err := NewCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCache: unexpected error: %v", err)
+	}
+
+	if _, _, _, ok := cache.Load(); ok {
+		t.Error("Load: ok = true on an empty cache")
+	}
+}
+
+func TestCacheLoadMissingImage(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewCache(dir)
+	if err != nil {
+		t.Fatalf("NewCache: unexpected error: %v", err)
+	}
+
+	srcImage := filepath.Join(dir, "src.jpg")
+	if err := os.WriteFile(srcImage, []byte("x"), 0644); err != nil {
+		t.Fatalf("failed to write source image: %v", err)
+	}
+	terminal := TerminalResponse{Filename: "display.jpg"}
+	if err := cache.Store(terminal, srcImage); err != nil {
+		t.Fatalf("Store: unexpected error: %v", err)
+	}
+
+	if err := os.Remove(cache.imagePath(filepath.Ext(terminal.Filename))); err != nil {
+		t.Fatalf("failed to remove cached image: %v", err)
+	}
+
+	if _, _, _, ok := cache.Load(); ok {
+		t.Error("Load: ok = true after the cached image disappeared")
+	}
+}
+
+// TestCacheStoreDoesNotAccumulate guards against a regression where
+// Store keyed the cached image's filename off the upstream TerminalResponse,
+// so a BYOS/TRMNL server returning a unique filename on every response
+// piled up one image file per call forever.
+func TestCacheStoreDoesNotAccumulate(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewCache(dir)
+	if err != nil {
+		t.Fatalf("NewCache: unexpected error: %v", err)
+	}
+
+	srcImage := filepath.Join(dir, "src.jpg")
+	if err := os.WriteFile(srcImage, []byte("first"), 0644); err != nil {
+		t.Fatalf("failed to write source image: %v", err)
+	}
+	if err := cache.Store(TerminalResponse{Filename: "2026-07-25T00-00-00.jpg"}, srcImage); err != nil {
+		t.Fatalf("Store: unexpected error: %v", err)
+	}
+	if err := os.WriteFile(srcImage, []byte("second"), 0644); err != nil {
+		t.Fatalf("failed to write source image: %v", err)
+	}
+	if err := cache.Store(TerminalResponse{Filename: "2026-07-25T00-05-00.jpg"}, srcImage); err != nil {
+		t.Fatalf("Store: unexpected error: %v", err)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "last_image.*"))
+	if err != nil {
+		t.Fatalf("Glob: unexpected error: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("cache dir has %d cached images after two Store calls with distinct filenames, want 1: %v", len(matches), matches)
+	}
+
+	_, imagePath, _, ok := cache.Load()
+	if !ok {
+		t.Fatal("Load: ok = false after Store")
+	}
+	gotData, err := os.ReadFile(imagePath)
+	if err != nil {
+		t.Fatalf("failed to read cached image: %v", err)
+	}
+	if string(gotData) != "second" {
+		t.Errorf("cached image bytes = %q, want %q", gotData, "second")
+	}
+}
@@ -0,0 +1,112 @@
+// Command trmnlctl is a small CLI for controlling a running trmnl-display
+// daemon over its Unix domain control socket.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// request/response mirror the daemon's control protocol in
+// controlsocket.go; trmnlctl ships as a standalone sibling binary so it
+// doesn't share an import path with the daemon.
+type request struct {
+	Cmd  string   `json:"cmd"`
+	Args []string `json:"args,omitempty"`
+}
+
+type response struct {
+	OK    bool        `json:"ok"`
+	Error string      `json:"error,omitempty"`
+	Data  interface{} `json:"data,omitempty"`
+}
+
+func defaultSocketPath() string {
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = os.TempDir()
+	}
+	return filepath.Join(runtimeDir, "trmnl-display.sock")
+}
+
+func main() {
+	socketPath := flag.String("socket", defaultSocketPath(), "Path to the trmnl-display control socket")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: trmnlctl [--socket=path] <command> [args...]\n\ncommands:\n")
+		fmt.Fprintln(os.Stderr, "  status                 show daemon status")
+		fmt.Fprintln(os.Stderr, "  refresh                force an immediate re-poll")
+		fmt.Fprintln(os.Stderr, "  pause                  stop polling until resumed")
+		fmt.Fprintln(os.Stderr, "  resume                 resume polling")
+		fmt.Fprintln(os.Stderr, "  set-refresh <seconds>  override the refresh interval")
+		fmt.Fprintln(os.Stderr, "  set-darkmode <bool>    enable/disable dark mode")
+		fmt.Fprintln(os.Stderr, "  last-image             print the path of the last displayed image")
+		fmt.Fprintln(os.Stderr, "  version                print the daemon's version")
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	resp, err := send(*socketPath, request{Cmd: args[0], Args: args[1:]})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !resp.OK {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", resp.Error)
+		os.Exit(1)
+	}
+
+	if resp.Data != nil {
+		printData(resp.Data)
+	}
+}
+
+func send(socketPath string, req request) (response, error) {
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return response{}, fmt.Errorf("failed to connect to %s: %v", socketPath, err)
+	}
+	defer conn.Close()
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return response{}, fmt.Errorf("failed to encode request: %v", err)
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return response{}, fmt.Errorf("failed to send request: %v", err)
+	}
+
+	scanner := bufio.NewScanner(conn)
+	if !scanner.Scan() {
+		return response{}, fmt.Errorf("no response from daemon: %v", scanner.Err())
+	}
+
+	var resp response
+	if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+		return response{}, fmt.Errorf("failed to decode response: %v", err)
+	}
+	return resp, nil
+}
+
+func printData(data interface{}) {
+	if s, ok := data.(string); ok {
+		fmt.Println(s)
+		return
+	}
+	pretty, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		fmt.Println(data)
+		return
+	}
+	fmt.Println(string(pretty))
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// socketRequest is one line of the control protocol sent by trmnlctl.
+type socketRequest struct {
+	Cmd  string   `json:"cmd"`
+	Args []string `json:"args,omitempty"`
+}
+
+// socketResponse is the daemon's reply to a socketRequest.
+type socketResponse struct {
+	OK    bool        `json:"ok"`
+	Error string      `json:"error,omitempty"`
+	Data  interface{} `json:"data,omitempty"`
+}
+
+// defaultSocketPath returns $XDG_RUNTIME_DIR/trmnl-display.sock, falling
+// back to the system temp dir if XDG_RUNTIME_DIR isn't set.
+func defaultSocketPath() string {
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = os.TempDir()
+	}
+	return filepath.Join(runtimeDir, "trmnl-display.sock")
+}
+
+// ControlSocket serves the trmnlctl control protocol over a Unix domain
+// socket, backed by the daemon's shared DaemonState.
+type ControlSocket struct {
+	listener net.Listener
+	state    *DaemonState
+	path     string
+}
+
+// ListenControlSocket creates (and listens on) the control socket at
+// path, removing any stale socket file left behind by a previous run.
+func ListenControlSocket(path string, state *DaemonState) (*ControlSocket, error) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale socket: %v", err)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on control socket: %v", err)
+	}
+
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set control socket permissions: %v", err)
+	}
+
+	return &ControlSocket{listener: listener, state: state, path: path}, nil
+}
+
+// Serve accepts connections until ctx is canceled or the listener is
+// closed.
+func (cs *ControlSocket) Serve(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+		cs.listener.Close()
+	}()
+
+	for {
+		conn, err := cs.listener.Accept()
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				fmt.Printf("Control socket accept error: %v\n", err)
+				return
+			}
+		}
+		go cs.handleConn(conn)
+	}
+}
+
+// Close shuts down the listener and removes the socket file.
+func (cs *ControlSocket) Close() error {
+	err := cs.listener.Close()
+	os.Remove(cs.path)
+	return err
+}
+
+func (cs *ControlSocket) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	encoder := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		var req socketRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			encoder.Encode(socketResponse{OK: false, Error: fmt.Sprintf("invalid request: %v", err)})
+			continue
+		}
+		encoder.Encode(cs.dispatch(req))
+	}
+}
+
+func (cs *ControlSocket) dispatch(req socketRequest) socketResponse {
+	switch req.Cmd {
+	case "status":
+		lastSuccessAt := ""
+		if t := cs.state.LastSuccessAt(); !t.IsZero() {
+			lastSuccessAt = t.Format(time.RFC3339)
+		}
+		return socketResponse{OK: true, Data: map[string]interface{}{
+			"paused":               cs.state.Paused(),
+			"refresh_seconds":      cs.state.RefreshSeconds(),
+			"dark_mode":            cs.state.DarkMode(),
+			"last_image":           cs.state.LastImage(),
+			"last_success_at":      lastSuccessAt,
+			"consecutive_failures": cs.state.ConsecutiveFailures(),
+			"using_cache":          cs.state.UsingCache(),
+			"version":              version,
+		}}
+
+	case "refresh":
+		cs.state.RequestRefresh()
+		return socketResponse{OK: true}
+
+	case "pause":
+		cs.state.SetPaused(true)
+		return socketResponse{OK: true}
+
+	case "resume":
+		cs.state.SetPaused(false)
+		cs.state.RequestRefresh()
+		return socketResponse{OK: true}
+
+	case "set-refresh":
+		if len(req.Args) != 1 {
+			return socketResponse{OK: false, Error: "set-refresh requires exactly one argument: <seconds>"}
+		}
+		secs, err := strconv.Atoi(req.Args[0])
+		if err != nil || secs <= 0 {
+			return socketResponse{OK: false, Error: "seconds must be a positive integer"}
+		}
+		cs.state.SetRefreshSeconds(secs)
+		return socketResponse{OK: true}
+
+	case "set-darkmode":
+		if len(req.Args) != 1 {
+			return socketResponse{OK: false, Error: "set-darkmode requires exactly one argument: <bool>"}
+		}
+		enabled, err := strconv.ParseBool(req.Args[0])
+		if err != nil {
+			return socketResponse{OK: false, Error: "expected true or false"}
+		}
+		cs.state.SetDarkMode(enabled)
+		return socketResponse{OK: true}
+
+	case "last-image":
+		return socketResponse{OK: true, Data: cs.state.LastImage()}
+
+	case "version":
+		return socketResponse{OK: true, Data: version}
+
+	default:
+		return socketResponse{OK: false, Error: fmt.Sprintf("unknown command: %q", req.Cmd)}
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// DaemonState holds the live, mutable state of the running daemon that
+// the control socket exposes to trmnlctl. All access goes through the
+// accessor methods below, which take the mutex; callers never touch the
+// fields directly.
+type DaemonState struct {
+	mu sync.Mutex
+
+	paused      bool
+	refreshSecs int
+	darkMode    bool
+	lastImage   string
+
+	lastSuccessAt       time.Time
+	consecutiveFailures int
+	usingCache          bool
+
+	refreshCh chan struct{}
+}
+
+// NewDaemonState creates a DaemonState seeded with the daemon's initial
+// dark mode setting. The refresh interval is unknown until the first
+// successful fetch, so it starts at zero.
+func NewDaemonState(darkMode bool) *DaemonState {
+	return &DaemonState{
+		darkMode:  darkMode,
+		refreshCh: make(chan struct{}, 1),
+	}
+}
+
+func (s *DaemonState) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+func (s *DaemonState) SetPaused(p bool) {
+	s.mu.Lock()
+	s.paused = p
+	s.mu.Unlock()
+}
+
+func (s *DaemonState) RefreshSeconds() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.refreshSecs
+}
+
+func (s *DaemonState) SetRefreshSeconds(secs int) {
+	s.mu.Lock()
+	s.refreshSecs = secs
+	s.mu.Unlock()
+}
+
+func (s *DaemonState) DarkMode() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.darkMode
+}
+
+func (s *DaemonState) SetDarkMode(d bool) {
+	s.mu.Lock()
+	s.darkMode = d
+	s.mu.Unlock()
+}
+
+func (s *DaemonState) LastImage() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastImage
+}
+
+func (s *DaemonState) SetLastImage(path string) {
+	s.mu.Lock()
+	s.lastImage = path
+	s.mu.Unlock()
+}
+
+func (s *DaemonState) LastSuccessAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastSuccessAt
+}
+
+func (s *DaemonState) SetLastSuccessAt(t time.Time) {
+	s.mu.Lock()
+	s.lastSuccessAt = t
+	s.mu.Unlock()
+}
+
+func (s *DaemonState) UsingCache() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.usingCache
+}
+
+func (s *DaemonState) SetUsingCache(b bool) {
+	s.mu.Lock()
+	s.usingCache = b
+	s.mu.Unlock()
+}
+
+// IncrementFailures records a failed fetch and returns the new
+// consecutive-failure count.
+func (s *DaemonState) IncrementFailures() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.consecutiveFailures++
+	return s.consecutiveFailures
+}
+
+// ResetFailures clears the consecutive-failure count after a successful
+// fetch.
+func (s *DaemonState) ResetFailures() {
+	s.mu.Lock()
+	s.consecutiveFailures = 0
+	s.mu.Unlock()
+}
+
+func (s *DaemonState) ConsecutiveFailures() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.consecutiveFailures
+}
+
+// RequestRefresh asks the main loop to skip its remaining sleep and
+// re-poll the API immediately. It is non-blocking: if a refresh is
+// already pending, this is a no-op.
+func (s *DaemonState) RequestRefresh() {
+	select {
+	case s.refreshCh <- struct{}{}:
+	default:
+	}
+}
+
+// RefreshRequested returns the channel waitForNextRefresh selects on to
+// detect a refresh request from the control socket.
+func (s *DaemonState) RefreshRequested() <-chan struct{} {
+	return s.refreshCh
+}
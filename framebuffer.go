@@ -8,6 +8,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 )
 
@@ -27,6 +28,16 @@ type Framebuffer struct {
 	stride     int
 	colorDepth ColorDepth
 	bounds     image.Rectangle
+
+	// Buffered-mode fields, populated by OpenFramebufferBuffered; zero
+	// value otherwise, so Set/Close behave exactly as before for the
+	// default direct-write mode.
+	buffered bool
+	mmapData []byte // the real, hardware-backed framebuffer memory
+	pan      *pageFlipper
+
+	dirtyMu sync.Mutex
+	dirty   image.Rectangle // accumulated since the last FlushAll; empty means clean
 }
 
 func OpenFramebuffer(device string) (*Framebuffer, error) {
@@ -164,7 +175,15 @@ func (fb *Framebuffer) mapMemory() error {
 }
 
 func (fb *Framebuffer) Close() error {
-	if fb.data != nil {
+	if fb.buffered {
+		// In buffered mode fb.data is either heap memory or a slice of
+		// mmapData (page-flipping); only mmapData needs unmapping.
+		if fb.mmapData != nil {
+			syscall.Munmap(fb.mmapData)
+			fb.mmapData = nil
+		}
+		fb.data = nil
+	} else if fb.data != nil {
 		syscall.Munmap(fb.data)
 		fb.data = nil
 	}
@@ -269,6 +288,22 @@ func (fb *Framebuffer) Set(x, y int, c color.Color) {
 		fb.data[offset+2] = r8
 		fb.data[offset+3] = a8
 	}
+
+	if fb.buffered {
+		fb.markDirty(image.Rect(x, y, x+1, y+1))
+	}
+}
+
+// markDirty unions r into the pending dirty rect that FlushAll will
+// publish.
+func (fb *Framebuffer) markDirty(r image.Rectangle) {
+	fb.dirtyMu.Lock()
+	if fb.dirty.Empty() {
+		fb.dirty = r
+	} else {
+		fb.dirty = fb.dirty.Union(r)
+	}
+	fb.dirtyMu.Unlock()
 }
 
 func rgb565ToRGBA(pixel uint16) color.RGBA {
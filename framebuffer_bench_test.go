@@ -0,0 +1,63 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// newBenchFramebuffer builds a Framebuffer entirely backed by heap
+// memory (no real /dev/fb0 device) so the benchmarks below can run in
+// any environment.
+func newBenchFramebuffer(buffered bool) *Framebuffer {
+	const width, height = 800, 480
+	depth := ColorDepth32
+	stride := width * (int(depth) / 8)
+
+	fb := &Framebuffer{
+		width:      width,
+		height:     height,
+		stride:     stride,
+		colorDepth: depth,
+		bounds:     image.Rect(0, 0, width, height),
+	}
+
+	fb.mmapData = make([]byte, stride*height)
+	if buffered {
+		fb.buffered = true
+		fb.data = make([]byte, stride*height)
+	} else {
+		fb.data = fb.mmapData
+	}
+
+	return fb
+}
+
+func BenchmarkDirectWriteFullScreen(b *testing.B) {
+	fb := newBenchFramebuffer(false)
+	c := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for y := 0; y < fb.height; y++ {
+			for x := 0; x < fb.width; x++ {
+				fb.Set(x, y, c)
+			}
+		}
+	}
+}
+
+func BenchmarkBufferedWriteAndFlushFullScreen(b *testing.B) {
+	fb := newBenchFramebuffer(true)
+	c := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for y := 0; y < fb.height; y++ {
+			for x := 0; x < fb.width; x++ {
+				fb.Set(x, y, c)
+			}
+		}
+		fb.FlushAll()
+	}
+}
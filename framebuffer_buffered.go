@@ -0,0 +1,104 @@
+package main
+
+import (
+	"image"
+	"time"
+)
+
+// OpenFramebufferBuffered opens device like OpenFramebuffer, but backs
+// Set/At with an off-screen buffer instead of writing straight into the
+// mmap'd framebuffer memory, so a caller mid-draw can never be scanned
+// out. Call Flush (or AutoFlush) to publish what's been drawn.
+//
+// On 32bpp devices whose driver supports FBIOPAN_DISPLAY, this instead
+// maps a second panel-sized region of framebuffer memory and flips
+// between them on Flush, avoiding the byte copy entirely.
+func OpenFramebufferBuffered(device string) (*Framebuffer, error) {
+	fb, err := OpenFramebuffer(device)
+	if err != nil {
+		return nil, err
+	}
+
+	fb.buffered = true
+	fb.mmapData = fb.data
+
+	if fb.colorDepth == ColorDepth32 {
+		if pan, err := newPageFlipper(fb); err == nil {
+			fb.pan = pan
+			fb.data = pan.backBuffer()
+			return fb, nil
+		}
+	}
+
+	// No page-flipping support (or not 32bpp): fall back to a heap-backed
+	// back buffer, seeded with whatever is currently on screen.
+	fb.data = make([]byte, len(fb.mmapData))
+	copy(fb.data, fb.mmapData)
+	return fb, nil
+}
+
+// Flush copies r (intersected with the framebuffer bounds) from the
+// back buffer into the real, visible framebuffer, one row at a time so
+// row padding (stride > width*bpp) is respected. On hardware with
+// page-flipping enabled, it ignores r and flips buffers instead, showing
+// the whole back buffer. The two buffers are never copied into each
+// other, so any area not redrawn since the previous flip shows whatever
+// that buffer last held.
+//
+// Flush is a no-op unless fb was opened with OpenFramebufferBuffered.
+func (fb *Framebuffer) Flush(r image.Rectangle) {
+	if !fb.buffered {
+		return
+	}
+
+	if fb.pan != nil {
+		fb.pan.flip()
+		return
+	}
+
+	r = r.Intersect(fb.bounds)
+	if r.Empty() {
+		return
+	}
+
+	bpp := int(fb.colorDepth) / 8
+	rowBytes := r.Dx() * bpp
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		offset := y*fb.stride + r.Min.X*bpp
+		copy(fb.mmapData[offset:offset+rowBytes], fb.data[offset:offset+rowBytes])
+	}
+}
+
+// FlushAll publishes whatever has been drawn since the last FlushAll
+// call. It's a no-op if nothing is dirty.
+func (fb *Framebuffer) FlushAll() {
+	fb.dirtyMu.Lock()
+	r := fb.dirty
+	fb.dirty = image.Rectangle{}
+	fb.dirtyMu.Unlock()
+
+	if r.Empty() {
+		return
+	}
+	fb.Flush(r)
+}
+
+// AutoFlush starts a goroutine that calls FlushAll every interval. The
+// returned stop function halts it; callers should always call stop when
+// done with the framebuffer, before Close.
+func (fb *Framebuffer) AutoFlush(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fb.FlushAll()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
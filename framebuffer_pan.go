@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// ioctl request numbers from linux/fb.h.
+const (
+	fbioGetVScreenInfo = 0x4600
+	fbioPutVScreenInfo = 0x4601
+	fbioPanDisplay     = 0x4606
+)
+
+// fbBitfield mirrors struct fb_bitfield from linux/fb.h.
+type fbBitfield struct {
+	Offset   uint32
+	Length   uint32
+	MSBRight uint32
+}
+
+// fbVarScreeninfo mirrors struct fb_var_screeninfo from linux/fb.h. All
+// fields are __u32 (or arrays/structs of __u32), so there's no
+// cross-platform padding to worry about.
+type fbVarScreeninfo struct {
+	XRes        uint32
+	YRes        uint32
+	XResVirtual uint32
+	YResVirtual uint32
+	XOffset     uint32
+	YOffset     uint32
+
+	BitsPerPixel uint32
+	Grayscale    uint32
+
+	Red    fbBitfield
+	Green  fbBitfield
+	Blue   fbBitfield
+	Transp fbBitfield
+
+	NonStd uint32
+
+	Activate uint32
+
+	Height uint32
+	Width  uint32
+
+	AccelFlags uint32
+
+	Pixclock    uint32
+	LeftMargin  uint32
+	RightMargin uint32
+	UpperMargin uint32
+	LowerMargin uint32
+	HsyncLen    uint32
+	VsyncLen    uint32
+	Sync        uint32
+	Vmode       uint32
+	Rotate      uint32
+	Colorspace  uint32
+	Reserved    [4]uint32
+}
+
+// pageFlipper implements true, copy-free double buffering: the
+// framebuffer memory is mapped tall enough to hold two panels stacked
+// vertically (top half + bottom half), and flipping just pans the display to
+// whichever half was last written, instead of the CPU copying pixels.
+type pageFlipper struct {
+	fb       *Framebuffer
+	frontOff int // byte offset of the currently visible buffer
+	backOff  int // byte offset of the buffer Set() is currently writing into
+}
+
+// newPageFlipper attempts to enable FBIOPAN_DISPLAY-based page flipping
+// on fb. It fails harmlessly (returning an error) on any hardware or
+// driver that doesn't support double-height virtual resolution, in
+// which case the caller should fall back to a heap-backed back buffer.
+func newPageFlipper(fb *Framebuffer) (*pageFlipper, error) {
+	var info fbVarScreeninfo
+	if err := fbIoctl(fb.file.Fd(), fbioGetVScreenInfo, &info); err != nil {
+		return nil, fmt.Errorf("failed to read screen info: %v", err)
+	}
+
+	info.YResVirtual = uint32(fb.height) * 2
+	if err := fbIoctl(fb.file.Fd(), fbioPutVScreenInfo, &info); err != nil {
+		return nil, fmt.Errorf("panel does not support double-height panning: %v", err)
+	}
+
+	size := fb.stride * fb.height * 2
+	data, err := syscall.Mmap(int(fb.file.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mmap double-height framebuffer: %v", err)
+	}
+
+	// Only now that the bigger mapping succeeded do we drop the original
+	// single-height one.
+	syscall.Munmap(fb.mmapData)
+	fb.mmapData = data
+
+	return &pageFlipper{fb: fb, frontOff: 0, backOff: fb.stride * fb.height}, nil
+}
+
+func (p *pageFlipper) backBuffer() []byte {
+	return p.fb.mmapData[p.backOff : p.backOff+p.fb.stride*p.fb.height]
+}
+
+// flip pans the display to the buffer that's been written to since the
+// last flip, then swaps roles so subsequent draws target the
+// now-hidden buffer.
+func (p *pageFlipper) flip() {
+	var info fbVarScreeninfo
+	if err := fbIoctl(p.fb.file.Fd(), fbioGetVScreenInfo, &info); err != nil {
+		return
+	}
+
+	info.YOffset = uint32(p.backOff / p.fb.stride)
+	if err := fbIoctl(p.fb.file.Fd(), fbioPanDisplay, &info); err != nil {
+		return
+	}
+
+	p.frontOff, p.backOff = p.backOff, p.frontOff
+	p.fb.data = p.backBuffer()
+}
+
+func fbIoctl(fd uintptr, request uintptr, info *fbVarScreeninfo) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(unsafe.Pointer(info)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
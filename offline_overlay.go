@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// drawOfflineOverlay stamps a small "offline since HH:MM" label in the
+// bottom-left corner of fb, over whatever was just redrawn there. It's
+// only meaningful on the native framebuffer path; the show_img fallback
+// has no equivalent hook.
+func drawOfflineOverlay(fb *Framebuffer, since time.Time) {
+	label := fmt.Sprintf("offline since %s", since.Format("15:04"))
+	face := basicfont.Face7x13
+
+	bounds := fb.Bounds()
+	textX := bounds.Min.X + 4
+	textY := bounds.Max.Y - 4
+	const padding = 3
+
+	width := font.MeasureString(face, label).Ceil()
+	bgRect := image.Rect(textX-padding, textY-face.Height-padding, textX+width+padding, textY+padding).Intersect(bounds)
+	draw.Draw(fb, bgRect, image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	drawer := &font.Drawer{
+		Dst:  fb,
+		Src:  image.NewUniform(color.Black),
+		Face: face,
+		Dot: fixed.Point26_6{
+			X: fixed.I(textX),
+			Y: fixed.I(textY),
+		},
+	}
+	drawer.DrawString(label)
+}
@@ -0,0 +1,201 @@
+// Package render implements dithering and palette-mapping for e-paper
+// targets: the TRMNL panel is 1-bit (newer ones are 7-color), and images
+// fetched from the API need tone mapping before they can be shown on it.
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// DitherMode selects how Quantize maps continuous-tone pixels onto a
+// limited palette.
+type DitherMode int
+
+const (
+	// DitherNone maps each pixel to its nearest palette entry with no
+	// dithering at all.
+	DitherNone DitherMode = iota
+	// DitherBayer applies an ordered 8x8 Bayer matrix before mapping.
+	DitherBayer
+	// DitherFloyd applies Floyd-Steinberg error diffusion.
+	DitherFloyd
+)
+
+// ParseDitherMode parses the --dither flag value.
+func ParseDitherMode(s string) (DitherMode, error) {
+	switch s {
+	case "", "none":
+		return DitherNone, nil
+	case "bayer":
+		return DitherBayer, nil
+	case "floyd":
+		return DitherFloyd, nil
+	default:
+		return DitherNone, fmt.Errorf("unknown dither mode %q (want none, bayer, or floyd)", s)
+	}
+}
+
+// Built-in palettes for the --palette flag.
+var (
+	// PaletteBW is the classic 1-bit e-paper palette.
+	PaletteBW = color.Palette{color.Black, color.White}
+
+	// PaletteTRMNL7 approximates the 7-color palette exposed by newer
+	// TRMNL e-paper panels.
+	PaletteTRMNL7 = color.Palette{
+		color.RGBA{0, 0, 0, 255},
+		color.RGBA{255, 255, 255, 255},
+		color.RGBA{255, 0, 0, 255},
+		color.RGBA{0, 255, 0, 255},
+		color.RGBA{0, 0, 255, 255},
+		color.RGBA{255, 255, 0, 255},
+		color.RGBA{255, 128, 0, 255},
+	}
+)
+
+// ParsePalette parses the --palette flag value.
+func ParsePalette(s string) (color.Palette, error) {
+	switch s {
+	case "", "bw":
+		return PaletteBW, nil
+	case "trmnl7":
+		return PaletteTRMNL7, nil
+	default:
+		return nil, fmt.Errorf("unknown palette %q (want bw or trmnl7)", s)
+	}
+}
+
+// bayer8x8 is the standard ordered dithering threshold matrix, values
+// 0-63.
+var bayer8x8 = [8][8]int{
+	{0, 32, 8, 40, 2, 34, 10, 42},
+	{48, 16, 56, 24, 50, 18, 58, 26},
+	{12, 44, 4, 36, 14, 46, 6, 38},
+	{60, 28, 52, 20, 62, 30, 54, 22},
+	{3, 35, 11, 43, 1, 33, 9, 41},
+	{51, 19, 59, 27, 49, 17, 57, 25},
+	{15, 47, 7, 39, 13, 45, 5, 37},
+	{63, 31, 55, 23, 61, 29, 53, 21},
+}
+
+// bayerSpread is the full amplitude (in 0-255 units) of the threshold
+// bias applied by DitherBayer.
+const bayerSpread = 64.0
+
+// Quantize renders src onto a new *image.RGBA quantized to palette using
+// the given dither mode. src is never modified; quantization always
+// happens on a freshly allocated scratch buffer.
+func Quantize(src image.Image, palette color.Palette, mode DitherMode) *image.RGBA {
+	switch mode {
+	case DitherBayer:
+		return ditherBayer(src, palette)
+	case DitherFloyd:
+		return ditherFloyd(src, palette)
+	default:
+		return nearestMap(src, palette)
+	}
+}
+
+func nearestMap(src image.Image, palette color.Palette) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			dst.SetRGBA(x, y, quantizeColor(src.At(x, y), palette))
+		}
+	}
+	return dst
+}
+
+func ditherBayer(src image.Image, palette color.Palette) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := src.At(x, y).RGBA()
+			threshold := bayer8x8[(y-bounds.Min.Y)%8][(x-bounds.Min.X)%8]
+			spread := (float64(threshold)/64.0 - 0.5) * bayerSpread
+
+			biased := color.RGBA{
+				R: clamp8(float64(r>>8) + spread),
+				G: clamp8(float64(g>>8) + spread),
+				B: clamp8(float64(b>>8) + spread),
+				A: uint8(a >> 8),
+			}
+			dst.SetRGBA(x, y, quantizeColor(biased, palette))
+		}
+	}
+	return dst
+}
+
+// ditherFloyd implements Floyd-Steinberg error diffusion: the
+// quantization error at each pixel is propagated to its unprocessed
+// neighbors with weights 7/16 right, 3/16 down-left, 5/16 down, and
+// 1/16 down-right, processing rows top-to-bottom. Residual error is
+// clamped to [0,255] only at the point a pixel is quantized, so rounding
+// never compounds across the image.
+func ditherFloyd(src image.Image, palette color.Palette) *image.RGBA {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	// Running per-pixel, per-channel error, seeded from the source and
+	// updated in place as residuals diffuse forward.
+	errs := make([][3]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r, g, b, _ := src.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			errs[y*w+x] = [3]float64{float64(r >> 8), float64(g >> 8), float64(b >> 8)}
+		}
+	}
+
+	dst := image.NewRGBA(bounds)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			_, _, _, a := src.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+
+			cur := errs[y*w+x]
+			old := color.RGBA{R: clamp8(cur[0]), G: clamp8(cur[1]), B: clamp8(cur[2]), A: uint8(a >> 8)}
+			quantized := quantizeColor(old, palette)
+			dst.SetRGBA(bounds.Min.X+x, bounds.Min.Y+y, quantized)
+
+			errR := cur[0] - float64(quantized.R)
+			errG := cur[1] - float64(quantized.G)
+			errB := cur[2] - float64(quantized.B)
+
+			diffuseFloydError(errs, w, h, x+1, y, errR, errG, errB, 7.0/16)
+			diffuseFloydError(errs, w, h, x-1, y+1, errR, errG, errB, 3.0/16)
+			diffuseFloydError(errs, w, h, x, y+1, errR, errG, errB, 5.0/16)
+			diffuseFloydError(errs, w, h, x+1, y+1, errR, errG, errB, 1.0/16)
+		}
+	}
+	return dst
+}
+
+func diffuseFloydError(errs [][3]float64, w, h, x, y int, errR, errG, errB, weight float64) {
+	if x < 0 || x >= w || y < 0 || y >= h {
+		return
+	}
+	i := y*w + x
+	errs[i][0] += errR * weight
+	errs[i][1] += errG * weight
+	errs[i][2] += errB * weight
+}
+
+// quantizeColor finds c's nearest entry in palette and returns it as a
+// concrete color.RGBA.
+func quantizeColor(c color.Color, palette color.Palette) color.RGBA {
+	idx := palette.Index(c)
+	return color.RGBAModel.Convert(palette[idx]).(color.RGBA)
+}
+
+func clamp8(v float64) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
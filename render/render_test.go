@@ -0,0 +1,118 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// flatGrayImage returns a w x 1 image where every pixel is the same mid
+// gray, opaque. A flat input is the clearest way to see each dither
+// mode's characteristic pattern in a golden test.
+func flatGrayImage(w int, gray uint8) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, 1))
+	for x := 0; x < w; x++ {
+		img.SetRGBA(x, 0, color.RGBA{R: gray, G: gray, B: gray, A: 255})
+	}
+	return img
+}
+
+func rowColors(img *image.RGBA) []color.RGBA {
+	bounds := img.Bounds()
+	out := make([]color.RGBA, 0, bounds.Dx())
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		out = append(out, img.RGBAAt(x, bounds.Min.Y))
+	}
+	return out
+}
+
+var black = color.RGBA{0, 0, 0, 255}
+var white = color.RGBA{255, 255, 255, 255}
+
+func assertRow(t *testing.T, got []color.RGBA, want ...color.RGBA) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d pixels, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("pixel %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestQuantizeNoneFlatGray(t *testing.T) {
+	src := flatGrayImage(8, 128)
+	got := rowColors(Quantize(src, PaletteBW, DitherNone))
+	// 128/255 is (barely) past the black/white midpoint, so every pixel
+	// maps to the same nearest entry: no pattern at all.
+	assertRow(t, got, white, white, white, white, white, white, white, white)
+}
+
+func TestQuantizeBayerFlatGray(t *testing.T) {
+	src := flatGrayImage(8, 128)
+	got := rowColors(Quantize(src, PaletteBW, DitherBayer))
+	// The ordered matrix biases alternating pixels above/below the
+	// threshold, producing a checkerboard out of flat input.
+	assertRow(t, got, black, white, black, white, black, white, black, white)
+}
+
+func TestQuantizeFloydFlatGray(t *testing.T) {
+	src := flatGrayImage(8, 128)
+	got := rowColors(Quantize(src, PaletteBW, DitherFloyd))
+	// Error diffusion also breaks up flat input into an alternating
+	// pattern, but out of phase with Bayer's since it starts from the
+	// accumulated rounding error rather than a fixed matrix.
+	assertRow(t, got, white, black, white, black, white, black, white, black)
+}
+
+func TestQuantizeLeavesSourceUntouched(t *testing.T) {
+	src := flatGrayImage(4, 128)
+	before := *src // shallow copy of the header; Pix still aliases the same backing array
+	original := append([]byte(nil), src.Pix...)
+
+	Quantize(src, PaletteBW, DitherFloyd)
+
+	if src.Bounds() != before.Bounds() {
+		t.Fatalf("source bounds changed")
+	}
+	for i := range original {
+		if src.Pix[i] != original[i] {
+			t.Fatalf("source pixel data mutated at byte %d", i)
+		}
+	}
+}
+
+func TestParseDitherMode(t *testing.T) {
+	cases := map[string]DitherMode{
+		"":      DitherNone,
+		"none":  DitherNone,
+		"bayer": DitherBayer,
+		"floyd": DitherFloyd,
+	}
+	for input, want := range cases {
+		got, err := ParseDitherMode(input)
+		if err != nil {
+			t.Fatalf("ParseDitherMode(%q): unexpected error: %v", input, err)
+		}
+		if got != want {
+			t.Errorf("ParseDitherMode(%q) = %v, want %v", input, got, want)
+		}
+	}
+
+	if _, err := ParseDitherMode("bogus"); err == nil {
+		t.Error("ParseDitherMode(\"bogus\") should return an error")
+	}
+}
+
+func TestParsePalette(t *testing.T) {
+	if _, err := ParsePalette("bw"); err != nil {
+		t.Errorf("ParsePalette(\"bw\"): unexpected error: %v", err)
+	}
+	if p, err := ParsePalette("trmnl7"); err != nil || len(p) != 7 {
+		t.Errorf("ParsePalette(\"trmnl7\") = %v, %v; want 7 colors, no error", p, err)
+	}
+	if _, err := ParsePalette("bogus"); err == nil {
+		t.Error("ParsePalette(\"bogus\") should return an error")
+	}
+}
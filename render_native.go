@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+
+	"github.com/usetrmnl/trmnl-display/render"
+	_ "golang.org/x/image/bmp"
+	xdraw "golang.org/x/image/draw"
+)
+
+// decodeImageFile decodes a BMP, PNG, or JPEG file into an image.Image.
+// The format decoders are registered via blank import above, so
+// image.Decode picks the right one based on the file's magic bytes.
+func decodeImageFile(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %v", err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %v", err)
+	}
+	return img, nil
+}
+
+// renderToFramebuffer scales img to fit fb.Bounds() (preserving aspect
+// ratio and centering it), then blits the result into fb. Scaling is
+// done on a scratch RGBA buffer so the source image is never mutated,
+// and dark mode inverts the output when the source is 1-bit. When
+// dither is anything other than render.DitherNone, the scaled image is
+// additionally quantized to palette before the final blit.
+func renderToFramebuffer(fb *Framebuffer, img image.Image, darkMode bool, dither render.DitherMode, palette color.Palette) error {
+	dstBounds := fb.Bounds()
+	srcBounds := img.Bounds()
+
+	scale := fitScale(srcBounds.Dx(), srcBounds.Dy(), dstBounds.Dx(), dstBounds.Dy())
+	scaledW := int(float64(srcBounds.Dx())*scale + 0.5)
+	scaledH := int(float64(srcBounds.Dy())*scale + 0.5)
+
+	offX := dstBounds.Min.X + (dstBounds.Dx()-scaledW)/2
+	offY := dstBounds.Min.Y + (dstBounds.Dy()-scaledH)/2
+	target := image.Rect(offX, offY, offX+scaledW, offY+scaledH)
+
+	scratch := image.NewRGBA(dstBounds)
+	draw.Draw(scratch, dstBounds, image.NewUniform(color.White), image.Point{}, draw.Src)
+	xdraw.BiLinear.Scale(scratch, target, img, srcBounds, xdraw.Over, nil)
+
+	if darkMode && isOneBit(img) {
+		invertRGBA(scratch)
+	}
+
+	final := image.Image(scratch)
+	if dither != render.DitherNone {
+		final = render.Quantize(scratch, palette, dither)
+	}
+
+	draw.Draw(fb, dstBounds, final, dstBounds.Min, draw.Src)
+	return nil
+}
+
+// fitScale returns the largest scale factor that fits srcW x srcH inside
+// dstW x dstH without distorting the aspect ratio.
+func fitScale(srcW, srcH, dstW, dstH int) float64 {
+	if srcW == 0 || srcH == 0 {
+		return 1
+	}
+	sx := float64(dstW) / float64(srcW)
+	sy := float64(dstH) / float64(srcH)
+	if sx < sy {
+		return sx
+	}
+	return sy
+}
+
+// isOneBit reports whether img is a paletted image with at most two
+// colors, i.e. the 1-bit BMPs the TRMNL API serves.
+func isOneBit(img image.Image) bool {
+	pal, ok := img.(*image.Paletted)
+	return ok && len(pal.Palette) <= 2
+}
+
+func invertRGBA(img *image.RGBA) {
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		img.Pix[i] = 255 - img.Pix[i]
+		img.Pix[i+1] = 255 - img.Pix[i+1]
+		img.Pix[i+2] = 255 - img.Pix[i+2]
+	}
+}
+
+// saveDitheredDebugImage decodes imagePath, quantizes it with the
+// configured dither mode and palette, and writes the result as a PNG to
+// options.SaveDithered. It's a debugging aid for tuning --dither and
+// --palette without a physical panel attached.
+func saveDitheredDebugImage(imagePath string, options AppOptions) error {
+	img, err := decodeImageFile(imagePath)
+	if err != nil {
+		return err
+	}
+
+	quantized := render.Quantize(img, options.Palette, options.Dither)
+
+	out, err := os.Create(options.SaveDithered)
+	if err != nil {
+		return fmt.Errorf("failed to create debug output: %v", err)
+	}
+	defer out.Close()
+
+	if err := png.Encode(out, quantized); err != nil {
+		return fmt.Errorf("failed to encode debug output: %v", err)
+	}
+
+	if options.Verbose {
+		fmt.Printf("Saved dithered debug image: %s\n", options.SaveDithered)
+	}
+	return nil
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"image/color"
 	"io"
 	"net/http"
 	"os"
@@ -14,6 +16,8 @@ import (
 	"syscall"
 	"time"
 	"bufio"
+
+	"github.com/usetrmnl/trmnl-display/render"
 )
 
 // Version information
@@ -39,19 +43,29 @@ type Config struct {
 
 // AppOptions holds command line options
 type AppOptions struct {
-	DarkMode bool
-	Verbose  bool
-	BaseURL  string
+	DarkMode     bool
+	Verbose      bool
+	BaseURL      string
+	Renderer     string // "fb" (native framebuffer) or "external" (show_img)
+	SocketPath   string
+	Dither       render.DitherMode
+	Palette      color.Palette
+	SaveDithered string // debug: path to dump the dithered image to
+	CacheDir     string
+	NoCache      bool
 }
 
 //  exec.Command("sudo", "service", "gpm", "stop").Run()
 
-func main() {	
+func main() {
 	// Parse command line arguments
 	options := parseCommandLineArgs()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	// Set up signal handling for clean exit
-	setupSignalHandling()
+	setupSignalHandling(cancel)
 
 	// Check the environment first
 	if options.Verbose {
@@ -110,6 +124,13 @@ func main() {
 		fmt.Printf("Using base URL: %s\n", config.BaseURL)
 	}
 
+	// Pick (or auto-detect) the rendering backend once, up front, rather
+	// than re-probing /dev/fb0 on every single refresh.
+	options.Renderer = resolveRenderer(options.Renderer)
+	if options.Verbose {
+		fmt.Printf("Using renderer: %s\n", options.Renderer)
+	}
+
 	// Check if we're using trmnl.app or a custom server
 	isTerminusServer := !strings.Contains(config.BaseURL, "trmnl.app")
 
@@ -147,19 +168,71 @@ func main() {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	for {
-		processNextImage(tmpDir, config, options)
+	// The control socket exposes runtime state and commands (status,
+	// refresh, pause/resume, ...) to the trmnlctl CLI.
+	state := NewDaemonState(options.DarkMode)
+
+	socketPath := options.SocketPath
+	if socketPath == "" {
+		socketPath = defaultSocketPath()
+	}
+	ctrlSock, err := ListenControlSocket(socketPath, state)
+	if err != nil {
+		fmt.Printf("Error starting control socket: %v\n", err)
+		os.Exit(1)
+	}
+	defer ctrlSock.Close()
+	go ctrlSock.Serve(ctx)
+
+	if options.Verbose {
+		fmt.Printf("Control socket listening at %s\n", socketPath)
+	}
+
+	// Set up the offline cache and, if it already holds something from a
+	// previous run, show it immediately instead of a blank screen while
+	// we wait on the first network call.
+	var cache *Cache
+	if !options.NoCache {
+		cacheDir := options.CacheDir
+		if cacheDir == "" {
+			cacheDir, err = defaultCacheDir()
+			if err != nil {
+				fmt.Printf("Error determining cache directory: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		cache, err = NewCache(cacheDir)
+		if err != nil {
+			fmt.Printf("Error setting up cache: %v\n", err)
+			os.Exit(1)
+		}
+
+		if _, imagePath, savedAt, ok := cache.Load(); ok {
+			if err := displayImage(imagePath, options); err != nil {
+				fmt.Printf("Error displaying cached image: %v\n", err)
+			} else {
+				state.SetLastImage(imagePath)
+				state.SetLastSuccessAt(savedAt)
+				state.SetUsingCache(true)
+			}
+		}
+	}
+
+	for ctx.Err() == nil {
+		processNextImage(ctx, tmpDir, config, options, state, cache)
 	}
 }
 
 // setupSignalHandling sets up handlers for SIGINT, SIGTERM, and SIGHUP
-func setupSignalHandling() {
+// that cancel ctx so the main loop and control socket can shut down
+// cleanly.
+func setupSignalHandling(cancel context.CancelFunc) {
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 	go func() {
 		<-c
 		fmt.Println("\nReceived termination signal. Cleaning up...")
-		os.Exit(0)
+		cancel()
 	}()
 }
 
@@ -170,6 +243,13 @@ func parseCommandLineArgs() AppOptions {
 	verbose := flag.Bool("verbose", true, "Enable verbose output")
 	quiet := flag.Bool("q", false, "Quiet mode (disable verbose output)")
 	baseURL := flag.String("base-url", "", "Custom base URL for the TRMNL API (default: https://trmnl.app)")
+	renderer := flag.String("renderer", "", "Rendering backend: fb (native framebuffer) or external (show_img); auto-detected from /dev/fb0 if unset")
+	socketPath := flag.String("socket", "", "Path to the control socket (default: $XDG_RUNTIME_DIR/trmnl-display.sock)")
+	ditherFlag := flag.String("dither", "none", "Dither mode for e-paper targets: none, bayer, or floyd")
+	paletteFlag := flag.String("palette", "bw", "Palette for e-paper targets: bw or trmnl7")
+	saveDithered := flag.String("save-dithered", "", "Debug: save the dithered image to this path")
+	cacheDir := flag.String("cache-dir", "", "Directory for the offline cache (default: $XDG_CACHE_HOME/trmnl)")
+	noCache := flag.Bool("no-cache", false, "Disable the offline cache entirely")
 	flag.Parse()
 
 	if *showVersion {
@@ -178,28 +258,73 @@ func parseCommandLineArgs() AppOptions {
 		os.Exit(0)
 	}
 
+	dither, err := render.ParseDitherMode(*ditherFlag)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	palette, err := render.ParsePalette(*paletteFlag)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	return AppOptions{
-		DarkMode: *darkMode,
-		Verbose:  *verbose && !*quiet,
-		BaseURL:  *baseURL,
+		DarkMode:     *darkMode,
+		Verbose:      *verbose && !*quiet,
+		BaseURL:      *baseURL,
+		Renderer:     *renderer,
+		SocketPath:   *socketPath,
+		Dither:       dither,
+		Palette:      palette,
+		SaveDithered: *saveDithered,
+		CacheDir:     *cacheDir,
+		NoCache:      *noCache,
 	}
 }
 
-func processNextImage(tmpDir string, config Config, options AppOptions) {
+// resolveRenderer finalizes which rendering backend to use. An explicit
+// --renderer flag always wins; otherwise we auto-detect based on whether
+// /dev/fb0 is present, falling back to the show_img external tool for
+// e-paper setups that don't expose a standard Linux framebuffer.
+func resolveRenderer(preferred string) string {
+	switch preferred {
+	case "fb", "external":
+		return preferred
+	}
+
+	if _, err := os.Stat("/dev/fb0"); err == nil {
+		return "fb"
+	}
+	return "external"
+}
+
+func processNextImage(ctx context.Context, tmpDir string, config Config, options AppOptions, state *DaemonState, cache *Cache) {
 	// Use defer and recover to handle any panics
 	defer func() {
 		if r := recover(); r != nil {
 			fmt.Printf("Recovered from panic: %v\n", r)
-			time.Sleep(60 * time.Second)
+			sleepOrDone(ctx, 60*time.Second)
 		}
 	}()
 
+	if state.Paused() {
+		if options.Verbose {
+			fmt.Println("Paused; waiting for resume or refresh command...")
+		}
+		waitForNextRefresh(ctx, state, 3600)
+		return
+	}
+
+	// The control socket may have changed dark mode since we last fetched.
+	options.DarkMode = state.DarkMode()
+
 	// Get the TRMNL display
 	apiURL := strings.TrimRight(config.BaseURL, "/") + "/api/display"
 	req, err := http.NewRequest("GET", apiURL, nil)
 	if err != nil {
-		fmt.Printf("Error creating request: %v\n", err)
-		time.Sleep(60 * time.Second)
+		handleFetchFailure(ctx, options, state, cache, "Error creating request", err)
 		return
 	}
 
@@ -225,18 +350,17 @@ func processNextImage(tmpDir string, config Config, options AppOptions) {
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		fmt.Printf("Error fetching display: %v\n", err)
-		time.Sleep(60 * time.Second)
+		handleFetchFailure(ctx, options, state, cache, "Error fetching display", err)
 		return
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		fmt.Printf("Error fetching display from %s: status code %d\n", apiURL, resp.StatusCode)
 		if options.Verbose && resp.StatusCode == 404 {
 			fmt.Printf("API endpoint not found. Please verify the base URL is correct.\n")
 		}
-		time.Sleep(60 * time.Second)
+		handleFetchFailure(ctx, options, state, cache, fmt.Sprintf("Error fetching display from %s", apiURL),
+			fmt.Errorf("status code %d", resp.StatusCode))
 		return
 	}
 
@@ -244,8 +368,7 @@ func processNextImage(tmpDir string, config Config, options AppOptions) {
 	var terminal TerminalResponse
 	decoder := json.NewDecoder(resp.Body)
 	if err := decoder.Decode(&terminal); err != nil {
-		fmt.Printf("Error parsing JSON: %v\n", err)
-		time.Sleep(60 * time.Second)
+		handleFetchFailure(ctx, options, state, cache, "Error parsing JSON", err)
 		return
 	}
 
@@ -261,8 +384,7 @@ func processNextImage(tmpDir string, config Config, options AppOptions) {
 	// Download the image
 	imgResp, err := http.Get(terminal.ImageURL)
 	if err != nil {
-		fmt.Printf("Error downloading image: %v\n", err)
-		time.Sleep(60 * time.Second)
+		handleFetchFailure(ctx, options, state, cache, "Error downloading image", err)
 		return
 	}
 	defer imgResp.Body.Close()
@@ -270,17 +392,15 @@ func processNextImage(tmpDir string, config Config, options AppOptions) {
 	// Create the file
 	out, err := os.Create(filePath)
 	if err != nil {
-		fmt.Printf("Error creating file: %v\n", err)
-		time.Sleep(60 * time.Second)
+		handleFetchFailure(ctx, options, state, cache, "Error creating file", err)
 		return
 	}
 
 	// Copy the image data to the file
 	_, err = io.Copy(out, imgResp.Body)
 	if err != nil {
-		fmt.Printf("Error saving image: %v\n", err)
 		out.Close()
-		time.Sleep(60 * time.Second)
+		handleFetchFailure(ctx, options, state, cache, "Error saving image", err)
 		return
 	}
 	out.Close()
@@ -288,39 +408,112 @@ func processNextImage(tmpDir string, config Config, options AppOptions) {
 	// Display the image
 	err = displayImage(filePath, options)
 	if err != nil {
-		fmt.Printf("Error displaying image: %v\n", err)
-		time.Sleep(60 * time.Second)
+		handleFetchFailure(ctx, options, state, cache, "Error displaying image", err)
 		return
 	}
+	state.SetLastImage(filePath)
+	state.SetUsingCache(false)
+	state.ResetFailures()
+	state.SetLastSuccessAt(time.Now())
+
+	if cache != nil {
+		if err := cache.Store(terminal, filePath); err != nil && options.Verbose {
+			fmt.Printf("Error updating cache: %v\n", err)
+		}
+	}
 
 	// Set default refresh rate if not provided
 	refreshRate := terminal.RefreshRate
 	if refreshRate <= 0 {
 		refreshRate = 60
 	}
+	state.SetRefreshSeconds(refreshRate)
 
-	done := 0
+	waitForNextRefresh(ctx, state, refreshRate)
+}
 
+// waitForNextRefresh blocks for refreshSeconds, returning early if ctx is
+// canceled (shutdown), a keypress arrives on stdin, or the control
+// socket receives a "refresh" (or "resume") command.
+func waitForNextRefresh(ctx context.Context, state *DaemonState, refreshSeconds int) {
+	keypress := make(chan struct{})
 	go func() {
 		scanner := bufio.NewScanner(os.Stdin)
-		for scanner.Scan() {
+		if scanner.Scan() {
 			fmt.Println("Keypress...skipping to next update")
-			done = 1
-			break
+			close(keypress)
 		}
 	}()
-	
-	out:
-	// Sleep for the refresh rate
-	for i := 0; i < refreshRate; i++ {
-	    time.Sleep(time.Second) // sleep one second at a time
-	    if done == 1 {
-	        break out
-	    }
+
+	timer := time.NewTimer(time.Duration(refreshSeconds) * time.Second)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-keypress:
+	case <-state.RefreshRequested():
+	case <-timer.C:
+	}
+}
+
+// sleepOrDone sleeps for d, or returns early if ctx is canceled, so error
+// backoffs don't delay shutdown.
+func sleepOrDone(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
 	}
 }
 
 func displayImage(imagePath string, options AppOptions) error {
+	if options.SaveDithered != "" {
+		if err := saveDitheredDebugImage(imagePath, options); err != nil {
+			fmt.Printf("Error saving dithered debug image: %v\n", err)
+		}
+	}
+
+	if options.Renderer == "fb" {
+		if err := displayImageNative(imagePath, options); err != nil {
+			if options.Verbose {
+				fmt.Printf("Native framebuffer render failed (%v); falling back to show_img\n", err)
+			}
+			return displayImageExternal(imagePath, options)
+		}
+		return nil
+	}
+	return displayImageExternal(imagePath, options)
+}
+
+// displayImageNative decodes the image and blits it straight into the
+// Linux framebuffer, without shelling out to show_img. This is the path
+// for standard LCD panels exposing /dev/fb0; e-paper setups still rely
+// on displayImageExternal.
+func displayImageNative(imagePath string, options AppOptions) error {
+	fb, err := OpenFramebuffer("")
+	if err != nil {
+		return fmt.Errorf("failed to open framebuffer: %v", err)
+	}
+	defer fb.Close()
+
+	img, err := decodeImageFile(imagePath)
+	if err != nil {
+		return err
+	}
+
+	if err := renderToFramebuffer(fb, img, options.DarkMode, options.Dither, options.Palette); err != nil {
+		return fmt.Errorf("failed to render to framebuffer: %v", err)
+	}
+
+	if options.Verbose {
+		fmt.Printf("Displayed: %s\n", imagePath)
+		fmt.Println("Framebuffer update completed")
+	}
+	return nil
+}
+
+func displayImageExternal(imagePath string, options AppOptions) error {
 //
 // N.B (Larry Bank)
 // This update can use one of 3 temperature/panel profiles
@@ -342,7 +535,7 @@ func displayImage(imagePath string, options AppOptions) error {
 
         err := exec.Command("show_img", sb.String(), sb2.String(), "mode=fast").Run()
         if err != nil {
-		fmt.Println("show_img tool missing; build it and try again; error = %v", err)
+		fmt.Printf("show_img tool missing; build it and try again; error = %v\n", err)
 		os.Exit(0);
         }
 	if options.Verbose {